@@ -58,6 +58,9 @@ EBPF_UNPROVISIONED_FILE="$MANIFEST_DIR/ebpf-unprovisioned.txt"
 EBPF_SOCKET="$MANIFEST_DIR/ebpf-supervisor.sock"
 EBPF_SUPERVISOR_CONF="$MANIFEST_DIR/ebpf/supervisord.conf"
 COLLECTOR_SUPERVISOR_CONF="$MANIFEST_DIR/collector/supervisord.conf"
+MANIFEST_FILE="$MANIFEST_DIR/manifest.json"
+MANIFEST_COMPLETE_FILE="$MANIFEST_DIR/manifest-complete.txt"
+STAGING_DIR="$MANIFEST_DIR/staging"
 
 # sanity check: if bootstrap is restarted and bootstrap marker is found, it's likely supervisor restart failed
 # perhaps a bad supervisord.conf file was sent - in any case, re-attempt bootstrapping on latest manifest
@@ -83,11 +86,16 @@ if [ -f "$BOOTSTRAPPED_FILE" ]; then
 fi
 
 # Function to make API request with error handling
+# Usage: make_api_request <url> <output file> [max attempts, default 5]
+# Transient failures are retried with exponential backoff (2s, 4s, 8s, ...) plus random jitter so a fleet
+# of collectors doesn't retry in lockstep, auth and not found errors are fatal
 make_api_request() {
     local url="$1"
     local output_file="$2"
-    local max_retries=3
+    local max_retries="${3:-5}"
     local retry_count=0
+    local retry_delay=2
+    local sleep_seconds
     local http_code
 
     while [ $retry_count -lt $max_retries ]; do
@@ -109,8 +117,14 @@ make_api_request() {
         else
             retry_count=$((retry_count + 1))
             if [ $retry_count -lt $max_retries ]; then
-                log_warn "Request failed (HTTP $http_code). Retrying ($retry_count/$max_retries)..."
-                sleep 2
+                sleep_seconds=$((retry_delay + RANDOM % retry_delay))
+                if [ "$http_code" = "000" ]; then
+                    log_warn "Request failed (could not connect). Retrying in ${sleep_seconds}s ($retry_count/$max_retries)..."
+                else
+                    log_warn "Request failed (HTTP $http_code). Retrying in ${sleep_seconds}s ($retry_count/$max_retries)..."
+                fi
+                sleep "$sleep_seconds"
+                retry_delay=$((retry_delay * 2))
             else
                 log_error "Request failed after $max_retries attempts (HTTP $http_code)"
                 return 1
@@ -121,6 +135,110 @@ make_api_request() {
     return 1
 }
 
+# Check that a previously downloaded manifest and every file it lists are present on the volume
+cached_manifest_complete() {
+    local files_count
+    local cached_path
+
+    if [ ! -f "$MANIFEST_FILE" ] || [ ! -f "$MANIFEST_COMPLETE_FILE" ]; then
+        return 1
+    fi
+
+    # the marker is removed before files are moved into place and written again once manifest.json is replaced,
+    # so a bootstrap interrupted anywhere in between leaves no marker
+    if [ "$(cat "$MANIFEST_COMPLETE_FILE")" != "$(jq -r '.manifest_version' "$MANIFEST_FILE" 2>/dev/null)" ]; then
+        log_warn "Cached manifest was not fully downloaded"
+        return 1
+    fi
+
+    files_count=$(jq -r '.files | length' "$MANIFEST_FILE" 2>/dev/null) || return 1
+    if [ -z "$files_count" ] || [ "$files_count" = "null" ] || [ "$files_count" -eq 0 ]; then
+        return 1
+    fi
+
+    while IFS= read -r cached_path; do
+        if [ ! -f "$MANIFEST_DIR/$cached_path" ]; then
+            log_warn "Cached manifest is incomplete: $cached_path is missing"
+            return 1
+        fi
+    done < <(jq -r '.files[] | select(.path != null and .container != null) | "\(.container)/\(.path)"' "$MANIFEST_FILE")
+
+    return 0
+}
+
+# Provision both containers from the manifest on disk and hand over to the real supervisord config
+finish_bootstrap() {
+    log_info "Bootstrap completed successfully!"
+    log_info "Manifest version: $MANIFEST_VERSION"
+    log_info "Files downloaded: $FILES_COUNT"
+    log_info "Location: $MANIFEST_DIR"
+
+    if [ "${COLLECTOR_PROFILE:-}" = "minimal" ]; then
+        # Minimal profile deploys no eBPF container, so there is no socket to wait for
+        log_info "Minimal profile, skipping eBPF supervisor socket wait"
+        date > "$EBPF_UNPROVISIONED_FILE"
+        log_info "eBPF unprovisioned marker written to: $EBPF_UNPROVISIONED_FILE"
+    else
+        # Wait for eBPF supervisor socket (up to 30 seconds)
+        WAIT_SECONDS=30
+        EBPF_SOCKET_EXISTS=false
+
+        log_info "Waiting up to ${WAIT_SECONDS}s for eBPF supervisor socket..."
+        for i in $(seq 1 $WAIT_SECONDS); do
+            if [ -S "$EBPF_SOCKET" ]; then
+                log_info "eBPF supervisor socket found after ${i}s"
+                EBPF_SOCKET_EXISTS=true
+                break
+            fi
+            sleep 1
+        done
+
+        if [ "$EBPF_SOCKET_EXISTS" = true ]; then
+            # Socket exists - reload eBPF supervisor
+            log_info "Reloading eBPF supervisor configuration..."
+            supervisorctl -c "$EBPF_SUPERVISOR_CONF" reread
+            supervisorctl -c "$EBPF_SUPERVISOR_CONF" update
+        else
+            # Socket not found - mark as unprovisioned
+            log_warn "eBPF supervisor socket not found after ${WAIT_SECONDS}s"
+            log_warn "Marking eBPF agent as unprovisioned"
+            date > "$EBPF_UNPROVISIONED_FILE"
+            log_info "eBPF unprovisioned marker written to: $EBPF_UNPROVISIONED_FILE"
+        fi
+    fi
+
+    # Mark bootstrap as completed
+    # XXX: it may still fail on supervisorctl commands, but if it does, the integrity check will re-bootstrap
+    date > "$BOOTSTRAPPED_FILE"
+    log_info "Bootstrap marker written to: $BOOTSTRAPPED_FILE"
+
+    # reload supervisord config and start processes as indicated by new config (overwriting bootstrap config)
+    log_info "Reloading local supervisor configuration..."
+    supervisorctl -c "$COLLECTOR_SUPERVISOR_CONF" reread
+    supervisorctl -c "$COLLECTOR_SUPERVISOR_CONF" update
+
+    exit 0
+}
+
+# Boot from the last fully downloaded manifest rather than leaving the host uninstrumented when telemetry is unreachable
+# Usage: fall_back_to_cached_manifest <exit code> <error message>
+fall_back_to_cached_manifest() {
+    local exit_code="$1"
+    local message="$2"
+
+    rm -rf "$STAGING_DIR"
+    if ! cached_manifest_complete; then
+        log_error "$message"
+        exit "$exit_code"
+    fi
+
+    log_warn "$message, falling back to cached manifest"
+    MANIFEST_VERSION=$(jq -r '.manifest_version' "$MANIFEST_FILE")
+    FILES_COUNT=$(jq -r '.files | length' "$MANIFEST_FILE")
+    log_info "Using cached manifest $MANIFEST_FILE (version: $MANIFEST_VERSION, files: $FILES_COUNT)"
+    finish_bootstrap
+}
+
 # Step 1: Get latest manifest version
 log_info "Fetching latest manifest version..."
 LATEST_MANIFEST_URL="$BASE_URL/api/collector/latest-manifest?collector_secret=$(printf %s "$COLLECTOR_SECRET" | jq -sRr @uri)"
@@ -128,11 +246,10 @@ LATEST_MANIFEST_URL="$BASE_URL/api/collector/latest-manifest?collector_secret=$(
 TEMP_VERSION_FILE=$(mktemp)
 
 # shellcheck disable=SC2064
-trap "rm -f $TEMP_VERSION_FILE" EXIT
+trap "rm -f $TEMP_VERSION_FILE; rm -rf $STAGING_DIR" EXIT
 
 if ! make_api_request "$LATEST_MANIFEST_URL" "$TEMP_VERSION_FILE"; then
-    log_error "Failed to fetch latest manifest version"
-    exit 4
+    fall_back_to_cached_manifest 4 "Failed to fetch latest manifest version"
 fi
 
 MANIFEST_VERSION=$(jq -r '.version' "$TEMP_VERSION_FILE" 2>/dev/null)
@@ -148,16 +265,16 @@ log_info "Latest manifest version: $MANIFEST_VERSION"
 log_info "Downloading manifest version $MANIFEST_VERSION..."
 MANIFEST_URL="$BASE_URL/api/collector/manifest?collector_secret=$(printf %s "$COLLECTOR_SECRET" | jq -sRr @uri)&manifest_version=$MANIFEST_VERSION&container_structure_version=2"
 
-MANIFEST_FILE="$MANIFEST_DIR/manifest.json"
-
 # Create directory if it doesn't exist
 mkdir -p "$MANIFEST_DIR"
 
-TEMP_MANIFEST=$(mktemp)
+# The new manifest and its files are staged until all of them are downloaded, so a failed download leaves the cached manifest intact
+rm -rf "$STAGING_DIR"
+mkdir -p "$STAGING_DIR/files"
+
+TEMP_MANIFEST="$STAGING_DIR/manifest.json"
 if ! make_api_request "$MANIFEST_URL" "$TEMP_MANIFEST"; then
-    log_error "Failed to download manifest"
-    rm -f "$TEMP_MANIFEST"
-    exit 6
+    fall_back_to_cached_manifest 6 "Failed to download manifest"
 fi
 
 # Validate manifest structure
@@ -176,17 +293,13 @@ if [ -z "$FILES_COUNT" ] || [ "$FILES_COUNT" = "null" ]; then
     exit 8
 fi
 
-# Move to final location
-mv "$TEMP_MANIFEST" "$MANIFEST_FILE"
-log_info "Manifest saved to $MANIFEST_FILE (version: $MANIFEST_VERSION_CHECK, files: $FILES_COUNT)"
-
 # Step 3: Process each file in manifest
 log_info "Processing $FILES_COUNT files from manifest..."
 
 for i in $(seq 0 $((FILES_COUNT - 1))); do
-    FILE_PATH=$(jq -r ".files[$i].path" "$MANIFEST_FILE")
-    CONTAINER=$(jq -r ".files[$i].container" "$MANIFEST_FILE")
-    ACTIONS=$(jq -r ".files[$i].actions // [] | join(\",\")" "$MANIFEST_FILE")
+    FILE_PATH=$(jq -r ".files[$i].path" "$TEMP_MANIFEST")
+    CONTAINER=$(jq -r ".files[$i].container" "$TEMP_MANIFEST")
+    ACTIONS=$(jq -r ".files[$i].actions // [] | join(\",\")" "$TEMP_MANIFEST")
 
     if [ "$FILE_PATH" = "null" ] || [ "$CONTAINER" = "null" ]; then
         log_warn "Skipping file $i: missing path or container"
@@ -195,104 +308,63 @@ for i in $(seq 0 $((FILES_COUNT - 1))); do
 
     log_info "[$((i + 1))/$FILES_COUNT] Downloading: $CONTAINER/$FILE_PATH"
 
-    # Construct destination path
-    DEST_DIR="$MANIFEST_DIR/$CONTAINER/$(dirname "$FILE_PATH")"
-    DEST_FILE="$MANIFEST_DIR/$CONTAINER/$FILE_PATH"
+    # Construct staging path
+    STAGED_DIR="$STAGING_DIR/files/$CONTAINER/$(dirname "$FILE_PATH")"
+    STAGED_FILE="$STAGING_DIR/files/$CONTAINER/$FILE_PATH"
 
     # Create directory structure
-    mkdir -p "$DEST_DIR"
+    mkdir -p "$STAGED_DIR"
 
     # Download file
     FILE_URL="$BASE_URL/api/collector/manifest-file?collector_secret=$(printf %s "$COLLECTOR_SECRET" | jq -sRr @uri)&manifest_version=$MANIFEST_VERSION&path=$(printf %s "$FILE_PATH" | jq -sRr @uri)&container=$(printf %s "$CONTAINER" | jq -sRr @uri)&container_structure_version=2"
 
     TEMP_FILE=$(mktemp)
     if ! make_api_request "$FILE_URL" "$TEMP_FILE"; then
-        log_error "Failed to download file: $CONTAINER/$FILE_PATH"
         rm -f "$TEMP_FILE"
-        exit 9
+        fall_back_to_cached_manifest 9 "Failed to download file: $CONTAINER/$FILE_PATH"
     fi
 
-    # Move to final location
-    mv "$TEMP_FILE" "$DEST_FILE"
+    # Move to staging location
+    mv "$TEMP_FILE" "$STAGED_FILE"
 
     # Apply actions
     if echo "$ACTIONS" | grep -q "make_executable"; then
-        chmod +x "$DEST_FILE"
-        log_info "  Made executable: $DEST_FILE"
+        chmod +x "$STAGED_FILE"
+        log_info "  Made executable: $CONTAINER/$FILE_PATH"
     fi
-
-    log_info "  Saved to: $DEST_FILE"
 done
 
+# All files are downloaded, move them and the manifest to their final location
+# Drop the marker first, files on disk stop matching the cached manifest as soon as the first one is moved
+rm -f "$MANIFEST_COMPLETE_FILE"
+log_info "Moving downloaded files to $MANIFEST_DIR..."
+while IFS= read -r STAGED_PATH; do
+    mkdir -p "$MANIFEST_DIR/$(dirname "$STAGED_PATH")"
+    mv "$STAGING_DIR/files/$STAGED_PATH" "$MANIFEST_DIR/$STAGED_PATH"
+    log_info "  Saved to: $MANIFEST_DIR/$STAGED_PATH"
+done < <(cd "$STAGING_DIR/files" && find . -type f | sed 's|^\./||')
+
+mv "$TEMP_MANIFEST" "$MANIFEST_FILE"
+rm -rf "$STAGING_DIR"
+log_info "Manifest saved to $MANIFEST_FILE (version: $MANIFEST_VERSION_CHECK, files: $FILES_COUNT)"
+
+# Record that every file of this manifest is on disk, so it can be reused when telemetry is unreachable
+echo "$MANIFEST_VERSION_CHECK" > "$MANIFEST_COMPLETE_FILE"
+
 # Step 4: Download topology configuration (optional, with retries)
 log_info "Downloading topology configuration..."
 TOPOLOGY_URL="$BASE_URL/api/collector/configuration-file?collector_secret=$(printf %s "$COLLECTOR_SECRET" | jq -sRr @uri)&configuration_version=latest&file=topology.json&container_structure_version=2"
 TOPOLOGY_FILE="$MANIFEST_DIR/topology.json"
+# Topology is optional, so it gets fewer attempts than the manifest to keep bootstrap from stalling
 TOPOLOGY_MAX_ATTEMPTS=3
-TOPOLOGY_ATTEMPT=1
-TOPOLOGY_SUCCESS=false
-
-while [ "$TOPOLOGY_ATTEMPT" -le "$TOPOLOGY_MAX_ATTEMPTS" ]; do
-    TEMP_TOPOLOGY=$(mktemp)
-    if make_api_request "$TOPOLOGY_URL" "$TEMP_TOPOLOGY"; then
-        mv "$TEMP_TOPOLOGY" "$TOPOLOGY_FILE"
-        log_info "Topology configuration saved to: $TOPOLOGY_FILE"
-        TOPOLOGY_SUCCESS=true
-        break
-    else
-        rm -f "$TEMP_TOPOLOGY"
-        if [ "$TOPOLOGY_ATTEMPT" -lt "$TOPOLOGY_MAX_ATTEMPTS" ]; then
-            log_warn "Failed to download topology configuration (attempt $TOPOLOGY_ATTEMPT/$TOPOLOGY_MAX_ATTEMPTS, retrying...)"
-            sleep 2
-        fi
-        TOPOLOGY_ATTEMPT=$((TOPOLOGY_ATTEMPT + 1))
-    fi
-done
-
-if [ "$TOPOLOGY_SUCCESS" = false ]; then
-    log_warn "Failed to download topology configuration after $TOPOLOGY_MAX_ATTEMPTS attempts (continuing anyway)"
-fi
 
-log_info "Bootstrap completed successfully!"
-log_info "Manifest version: $MANIFEST_VERSION"
-log_info "Files downloaded: $FILES_COUNT"
-log_info "Location: $MANIFEST_DIR"
-
-# Wait for eBPF supervisor socket (up to 30 seconds)
-WAIT_SECONDS=30
-EBPF_SOCKET_EXISTS=false
-
-log_info "Waiting up to ${WAIT_SECONDS}s for eBPF supervisor socket..."
-for i in $(seq 1 $WAIT_SECONDS); do
-    if [ -S "$EBPF_SOCKET" ]; then
-        log_info "eBPF supervisor socket found after ${i}s"
-        EBPF_SOCKET_EXISTS=true
-        break
-    fi
-    sleep 1
-done
-
-if [ "$EBPF_SOCKET_EXISTS" = true ]; then
-    # Socket exists - reload eBPF supervisor
-    log_info "Reloading eBPF supervisor configuration..."
-    supervisorctl -c "$EBPF_SUPERVISOR_CONF" reread
-    supervisorctl -c "$EBPF_SUPERVISOR_CONF" update
+TEMP_TOPOLOGY=$(mktemp)
+if make_api_request "$TOPOLOGY_URL" "$TEMP_TOPOLOGY" "$TOPOLOGY_MAX_ATTEMPTS"; then
+    mv "$TEMP_TOPOLOGY" "$TOPOLOGY_FILE"
+    log_info "Topology configuration saved to: $TOPOLOGY_FILE"
 else
-    # Socket not found - mark as unprovisioned
-    log_warn "eBPF supervisor socket not found after ${WAIT_SECONDS}s"
-    log_warn "Marking eBPF agent as unprovisioned"
-    date > "$EBPF_UNPROVISIONED_FILE"
-    log_info "eBPF unprovisioned marker written to: $EBPF_UNPROVISIONED_FILE"
+    rm -f "$TEMP_TOPOLOGY"
+    log_warn "Failed to download topology configuration after $TOPOLOGY_MAX_ATTEMPTS attempts (continuing anyway)"
 fi
 
-# Mark bootstrap as completed
-# XXX: it may still fail on supervisorctl commands, but if it does, the integrity check will re-bootstrap
-date > "$BOOTSTRAPPED_FILE"
-log_info "Bootstrap marker written to: $BOOTSTRAPPED_FILE"
-
-# reload supervisord config and start processes as indicated by new config (overwriting bootstrap config)
-log_info "Reloading local supervisor configuration..."
-supervisorctl -c "$COLLECTOR_SUPERVISOR_CONF" reread
-supervisorctl -c "$COLLECTOR_SUPERVISOR_CONF" update
-
-exit 0
+finish_bootstrap
@@ -0,0 +1,549 @@
+#!/bin/bash
+
+# Better Stack collector command line tool
+# Installed as /usr/local/bin/collector in the collector image, run it with:
+#   docker exec -it better-stack-collector collector <command>
+
+set -uo pipefail
+
+# Color output for better readability
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m' # No Color
+
+MANIFEST_DIR="/var/lib/better-stack"
+BOOTSTRAPPED_FILE="$MANIFEST_DIR/bootstrapped.txt"
+EBPF_UNPROVISIONED_FILE="$MANIFEST_DIR/ebpf-unprovisioned.txt"
+EBPF_SOCKET="$MANIFEST_DIR/ebpf-supervisor.sock"
+EBPF_PREFLIGHT_FILE="$MANIFEST_DIR/ebpf-preflight.json"
+COLLECTOR_SUPERVISOR_CONF="$MANIFEST_DIR/collector/supervisord.conf"
+MANIFEST_FILE="$MANIFEST_DIR/manifest.json"
+VECTOR_CONFIG_DIR="/vector-config/current"
+
+BASE_URL="${BASE_URL:-https://telemetry.betterstack.com}"
+COLLECTOR_SECRET="${COLLECTOR_SECRET:-}"
+
+# Disk usage thresholds in percent
+DISK_WARN_PERCENT=80
+DISK_FAIL_PERCENT=90
+
+# Clock skew threshold in seconds, ACME and TLS start failing well before larger offsets
+CLOCK_SKEW_WARN_SECONDS=30
+
+# Warn when a certificate expires within 14 days
+CERT_WARN_SECONDS=1209600
+
+# OTLP HTTP address of the Vector source the eBPF agent sends traces to, see versions/0-default/vector.yaml
+OTLP_TEST_ENDPOINT="http://127.0.0.1:34320"
+
+# Number of most recent lines kept from each log file in a support bundle
+SUPPORT_BUNDLE_LOG_LINES=1000
+
+# Environment variables included in a support bundle, COLLECTOR_SECRET is only reported as set or not
+SUPPORT_BUNDLE_ENV_VARS="BASE_URL CLUSTER_COLLECTOR HOSTNAME INSTALLED_AS COLLECTOR_PROFILE VECTOR_LOG_FORMAT
+COLLECT_OTEL_HTTP_PORT COLLECT_OTEL_GRPC_PORT HTTP_PROXY HTTPS_PROXY NO_PROXY
+COLLECTOR_VERSION VECTOR_VERSION OBI_VERSION CLUSTER_AGENT_VERSION"
+
+FAILURES=0
+WARNINGS=0
+
+check_pass() {
+    echo -e "${GREEN}[PASS]${NC} $1"
+}
+
+# Informational line that is neither a pass nor a problem
+check_info() {
+    echo "[INFO] $1"
+}
+
+# check_warn <message> [remediation hint]
+check_warn() {
+    echo -e "${YELLOW}[WARN]${NC} $1"
+    if [ -n "${2:-}" ]; then
+        echo "       -> $2"
+    fi
+    WARNINGS=$((WARNINGS + 1))
+}
+
+# check_fail <message> [remediation hint]
+check_fail() {
+    echo -e "${RED}[FAIL]${NC} $1"
+    if [ -n "${2:-}" ]; then
+        echo "       -> $2"
+    fi
+    FAILURES=$((FAILURES + 1))
+}
+
+usage() {
+    echo "Usage: collector <command>"
+    echo
+    echo "Commands:"
+    echo "  doctor          Check collector health and environment, printing remediation hints"
+    echo "  test-connection Check DNS, TLS, latency and collector secret for Better Stack endpoints"
+    echo "  send-test       Inject a synthetic trace with a marker attribute into the Vector pipeline"
+    echo "  support-bundle  Collect redacted configs, recent logs and status into a tarball for support tickets"
+    echo "  help            Show this help"
+}
+
+latest_manifest_url() {
+    echo "$BASE_URL/api/collector/latest-manifest?collector_secret=$(printf %s "$COLLECTOR_SECRET" | jq -sRr @uri)"
+}
+
+doctor_bootstrap() {
+    if [ -f "$BOOTSTRAPPED_FILE" ]; then
+        local manifest_version="unknown"
+        if [ -f "$MANIFEST_FILE" ]; then
+            manifest_version=$(jq -r '.manifest_version' "$MANIFEST_FILE" 2>/dev/null || echo "unknown")
+        fi
+        check_pass "Bootstrapped on $(cat "$BOOTSTRAPPED_FILE") (manifest version: $manifest_version)"
+    else
+        check_fail "Collector has not finished bootstrapping" "Check /var/log/supervisor/bootstrap.err.log and bootstrap.out.log"
+    fi
+}
+
+doctor_processes() {
+    local status
+    local not_running
+
+    if ! status=$(supervisorctl -c "$COLLECTOR_SUPERVISOR_CONF" status 2>&1); then
+        # supervisorctl exits non-zero when any process is not running, so only fail on empty output
+        if [ -z "$status" ]; then
+            check_fail "Cannot query supervisor" "Check that supervisord is running: ps aux | grep supervisord"
+            return
+        fi
+    fi
+
+    not_running=$(echo "$status" | grep -v "RUNNING" | grep -v "^bootstrap[[:space:]]\+EXITED" || true)
+    if [ -z "$not_running" ]; then
+        check_pass "All supervisor processes are running"
+    else
+        check_fail "Some supervisor processes are not running:" "Check /var/log/supervisor/<process>.err.log"
+        echo "$not_running" | sed 's/^/         /'
+    fi
+}
+
+doctor_host_mounts() {
+    if [ -d /host/proc ] && [ -r /host/proc/meminfo ]; then
+        check_pass "Host /proc is visible under /host"
+    else
+        check_warn "Host /proc is not visible under /host, host metrics will be incomplete" "Include /proc in MOUNT_HOST_PATHS or use the default /:/host:ro mount"
+    fi
+}
+
+doctor_ebpf() {
+    if [ -f "$EBPF_UNPROVISIONED_FILE" ]; then
+        if [ "${COLLECTOR_PROFILE:-}" = "minimal" ]; then
+            check_pass "eBPF agent is disabled by the minimal profile"
+            return
+        fi
+        check_fail "eBPF agent was not provisioned (marked on $(cat "$EBPF_UNPROVISIONED_FILE"))" "Check that the better-stack-ebpf container is running: docker logs better-stack-ebpf"
+    elif [ -S "$EBPF_SOCKET" ]; then
+        check_pass "eBPF agent supervisor socket is present"
+    else
+        check_warn "eBPF agent supervisor socket not found at $EBPF_SOCKET" "Check that the better-stack-ebpf container is running: docker logs better-stack-ebpf"
+    fi
+
+    if [ -f "$EBPF_PREFLIGHT_FILE" ]; then
+        if [ "$(jq -r '.ok' "$EBPF_PREFLIGHT_FILE" 2>/dev/null)" = "true" ]; then
+            check_pass "eBPF pre-flight checks passed (kernel $(jq -r '.kernel_release' "$EBPF_PREFLIGHT_FILE"))"
+        else
+            while IFS=$'\t' read -r message hint; do
+                check_warn "eBPF pre-flight $message" "$hint"
+            done < <(jq -r '.checks | to_entries[] | select(.value.ok == false) | "\(.key): \(.value.value)\t\(.value.hint)"' "$EBPF_PREFLIGHT_FILE" 2>/dev/null)
+        fi
+    fi
+}
+
+doctor_connectivity() {
+    local http_code
+
+    http_code=$(curl -s -o /dev/null -w "%{http_code}" --max-time 10 "$(latest_manifest_url)")
+    case "$http_code" in
+        200)
+            check_pass "Reached $BASE_URL and collector secret is valid"
+            ;;
+        401|403)
+            check_fail "Collector secret was rejected by $BASE_URL (HTTP $http_code)" "Check COLLECTOR_SECRET"
+            ;;
+        000)
+            check_fail "Cannot reach $BASE_URL" "Check DNS, firewall and HTTPS_PROXY settings${CONNECTIVITY_HINT:-}"
+            ;;
+        *)
+            check_fail "Unexpected response from $BASE_URL (HTTP $http_code)" "Retry later, or contact hello@betterstack.com if it persists"
+            ;;
+    esac
+}
+
+doctor_clock_skew() {
+    local date_header
+    local remote_epoch
+    local skew
+
+    date_header=$(curl -sI --max-time 10 "$BASE_URL" | grep -i '^date:' | cut -d' ' -f2- | tr -d '\r')
+    if [ -z "$date_header" ] || ! remote_epoch=$(date -d "$date_header" +%s 2>/dev/null); then
+        check_warn "Could not determine clock skew (no Date header from $BASE_URL)"
+        return
+    fi
+
+    skew=$(( $(date +%s) - remote_epoch ))
+    skew=${skew#-}
+    if [ "$skew" -le "$CLOCK_SKEW_WARN_SECONDS" ]; then
+        check_pass "Clock skew is ${skew}s"
+    else
+        check_warn "Clock skew is ${skew}s, certificate issuance and TLS may fail" "Enable NTP time sync on the host (e.g. timedatectl set-ntp true)"
+    fi
+}
+
+doctor_certificates() {
+    local cert
+    local domain
+    local expiry
+    local found=false
+
+    for cert in /etc/letsencrypt/live/*/cert.pem; do
+        [ -f "$cert" ] || continue
+        found=true
+        domain=$(basename "$(dirname "$cert")")
+        expiry=$(openssl x509 -enddate -noout -in "$cert" | cut -d= -f2)
+
+        if ! openssl x509 -checkend 0 -noout -in "$cert" >/dev/null; then
+            check_fail "Certificate for $domain expired on $expiry" "Check certbot logs in /var/log/letsencrypt/"
+        elif ! openssl x509 -checkend "$CERT_WARN_SECONDS" -noout -in "$cert" >/dev/null; then
+            check_warn "Certificate for $domain expires soon ($expiry)" "Check certbot logs in /var/log/letsencrypt/"
+        else
+            check_pass "Certificate for $domain is valid until $expiry"
+        fi
+    done
+
+    # certbot layout is runtime-delivered, so finding nothing here does not mean no certificates are in use
+    if [ "$found" = false ]; then
+        check_info "No certificates found in /etc/letsencrypt/live, skipping certificate expiry check"
+    fi
+}
+
+doctor_disk() {
+    local path
+    local usage
+
+    for path in "$MANIFEST_DIR" /var/lib/vector; do
+        [ -d "$path" ] || continue
+        if ! usage=$(df -P "$path" 2>/dev/null | awk 'NR == 2 { sub("%", "", $5); print $5 }') || [ -z "$usage" ]; then
+            check_warn "Could not determine disk usage for $path"
+        elif [ "$usage" -ge "$DISK_FAIL_PERCENT" ]; then
+            check_fail "Disk usage for $path is ${usage}%" "Free up disk space on the host, Vector buffers stop accepting data when full"
+        elif [ "$usage" -ge "$DISK_WARN_PERCENT" ]; then
+            check_warn "Disk usage for $path is ${usage}%" "Free up disk space on the host"
+        else
+            check_pass "Disk usage for $path is ${usage}%"
+        fi
+    done
+}
+
+doctor() {
+    CONNECTIVITY_HINT=", or run: collector test-connection"
+
+    echo "Better Stack collector ${COLLECTOR_VERSION:-unknown} diagnostics"
+    echo
+
+    doctor_bootstrap
+    doctor_processes
+    doctor_host_mounts
+    doctor_ebpf
+    doctor_connectivity
+    doctor_clock_skew
+    doctor_certificates
+    doctor_disk
+
+    echo
+    echo "$FAILURES failed, $WARNINGS warnings"
+    [ "$FAILURES" -eq 0 ]
+}
+
+# Endpoints to test: BASE_URL plus scheme://host[:port] of every remote URL in the current Vector config,
+# skipping hosts built from environment variables (${VAR} or $VAR), which can't be resolved here
+connection_endpoints() {
+    echo "$BASE_URL"
+    if [ -d "$VECTOR_CONFIG_DIR" ]; then
+        grep -rhoE "https?://[^\"'[:space:]/?#]+" "$VECTOR_CONFIG_DIR"/ 2>/dev/null |
+            grep -vF '$' |
+            grep -vE "://(localhost|127\.0\.0\.1|\[::1\])(:|$)" | sort -u
+    fi
+}
+
+# Format curl timing in seconds as milliseconds
+ms() {
+    awk -v t="$1" 'BEGIN { printf "%.0fms", t * 1000 }'
+}
+
+test_endpoint() {
+    local url="$1"
+    local host
+    local addresses
+    local verbose_file
+    local timings
+    local http_code
+    local namelookup
+    local connect
+    local appconnect
+    local total
+    local tls
+    local summary
+
+    host=${url#*://}
+    host=${host%%:*}
+
+    if addresses=$(getent hosts "$host" | awk '{print $1}' | sort -u | xargs) && [ -n "$addresses" ]; then
+        check_pass "$host resolves to $addresses"
+    elif [ -n "${https_proxy:-}" ]; then
+        check_warn "$host does not resolve locally, relying on the proxy to resolve it"
+    else
+        check_fail "$host does not resolve" "Check DNS settings of the host and /etc/resolv.conf in the container"
+        return
+    fi
+
+    verbose_file=$(mktemp)
+    timings=$(curl -sv -o /dev/null --max-time 10 \
+        -w "%{http_code} %{time_namelookup} %{time_connect} %{time_appconnect} %{time_total}" \
+        "$url" 2>"$verbose_file")
+    read -r http_code namelookup connect appconnect total <<< "$timings"
+
+    if [ "${http_code:-000}" = "000" ]; then
+        check_fail "Cannot reach $url: $(grep '^curl:' "$verbose_file" | tail -n 1)" "Check firewall rules for outbound HTTPS and the proxy settings"
+        rm -f "$verbose_file"
+        return
+    fi
+
+    # Any HTTP response means the endpoint is reachable, status codes are checked by the secret validation
+    summary="dns: $(ms "$namelookup"), connect: $(ms "$connect")"
+    if [ "${url%%://*}" = "https" ]; then
+        summary="$summary, tls: $(ms "$appconnect")"
+    fi
+    check_pass "Reached $url (HTTP $http_code) in $(ms "$total") ($summary)"
+
+    tls=$(grep -E "SSL connection using|issuer:|expire date:|SSL certificate verify" "$verbose_file" | sed 's/^\* *//')
+    if [ -n "$tls" ]; then
+        echo "$tls" | sed 's/^/       /'
+    fi
+    rm -f "$verbose_file"
+}
+
+test_connection() {
+    local endpoint
+
+    # docker exec does not go through run_supervisord.sh, mirror uppercase proxy settings for curl here
+    export http_proxy="${http_proxy:-${HTTP_PROXY:-}}" https_proxy="${https_proxy:-${HTTPS_PROXY:-}}" no_proxy="${no_proxy:-${NO_PROXY:-}}"
+
+    echo "Better Stack collector ${COLLECTOR_VERSION:-unknown} connection test"
+    echo
+    if [ -n "$https_proxy" ] || [ -n "$http_proxy" ]; then
+        echo "Using proxy: HTTPS_PROXY=${https_proxy:-none}, HTTP_PROXY=${http_proxy:-none}, NO_PROXY=${no_proxy:-none}"
+    else
+        echo "No outbound proxy configured"
+    fi
+    echo
+
+    while IFS= read -r endpoint; do
+        test_endpoint "$endpoint"
+    done < <(connection_endpoints)
+
+    doctor_connectivity
+
+    echo
+    echo "$FAILURES failed, $WARNINGS warnings"
+    [ "$FAILURES" -eq 0 ]
+}
+
+# OTLP/protobuf ExportTraceServiceRequest with a single span for send-test: otlp_test_trace <test id>
+# Encoded by hand, the image has no protobuf tooling; field numbers follow opentelemetry/proto/trace/v1/trace.proto
+otlp_test_trace() {
+    perl -e '
+        sub varint { my $n = shift; my $out = ""; while ($n >= 0x80) { $out .= chr(($n & 0x7f) | 0x80); $n >>= 7 } return $out . chr($n) }
+        sub field { my ($number, $bytes) = @_; return varint($number << 3 | 2) . varint(length $bytes) . $bytes }
+        sub fixed64 { my ($number, $value) = @_; return varint($number << 3 | 1) . pack("Q<", $value) }
+        # KeyValue { key = 1; AnyValue value = 2 { string_value = 1 } }
+        sub attribute { my ($key, $value) = @_; return field(1, $key) . field(2, field(1, $value)) }
+
+        my ($test_id, $host, $now) = @ARGV;
+        (my $trace_id = $test_id) =~ s/-//g;
+
+        my $span = field(1, pack("H*", $trace_id))
+            . field(2, pack("H*", substr($trace_id, 0, 16)))
+            . field(5, "better_stack.collector.test_event")
+            . varint(6 << 3) . varint(1)
+            . fixed64(7, $now) . fixed64(8, $now)
+            . field(9, attribute("better_stack.test_event", $test_id));
+        my $resource = field(1, attribute("service.name", "better-stack-collector-test"))
+            . field(1, attribute("host.name", $host));
+        my $scope_spans = field(1, field(1, "collector send-test")) . field(2, $span);
+
+        binmode STDOUT;
+        print field(1, field(1, $resource) . field(2, $scope_spans));
+    ' "$1" "${HOSTNAME:-$(hostname)}" "$(date +%s%N)"
+}
+
+send_test() {
+    local type="trace"
+    local test_id
+    local http_code
+
+    while [ $# -gt 0 ]; do
+        case "$1" in
+            --type)
+                type="${2:-}"
+                shift 2 || break
+                ;;
+            --type=*)
+                type="${1#--type=}"
+                shift
+                ;;
+            *)
+                echo "Unknown option: $1" >&2
+                return 1
+                ;;
+        esac
+    done
+
+    # The eBPF agent source only carries traces, logs and metrics sent to it may be dropped by the pipeline
+    if [ "$type" != "trace" ]; then
+        echo "Usage: collector send-test [--type trace]" >&2
+        echo "Only traces are supported, the pipeline source used for test events carries eBPF traces only" >&2
+        return 1
+    fi
+
+    test_id=$(cat /proc/sys/kernel/random/uuid)
+    http_code=$(otlp_test_trace "$test_id" |
+        curl -s -o /dev/null -w "%{http_code}" --max-time 10 -X POST \
+            -H "Content-Type: application/x-protobuf" --data-binary @- "$OTLP_TEST_ENDPOINT/v1/traces")
+
+    if [ "$http_code" != "200" ]; then
+        echo -e "${RED}Failed to send test trace to Vector at $OTLP_TEST_ENDPOINT/v1/traces (HTTP $http_code)${NC}" >&2
+        echo "Check that Vector is running: supervisorctl -c $COLLECTOR_SUPERVISOR_CONF status" >&2
+        return 1
+    fi
+
+    echo -e "${GREEN}Test trace accepted by Vector${NC}"
+    echo "Marker attribute: better_stack.test_event = $test_id"
+    echo "Search for the marker in Better Stack to verify enrichment and delivery,"
+    echo "or watch it pass through Vector with: vector tap 'ebpf_otel*'"
+}
+
+# Redact secrets in place: the collector secret itself, credentials in URLs, PEM private keys and
+# values of secret-looking keys (e.g. token, password, X-Api-Key, private_key) in YAML, JSON, header, env and query string formats.
+# Quoted values are redacted up to the closing quote, unquoted ones up to the end of the line (or , & } in JSON and query strings)
+redact_file() {
+    perl -pi -e '
+        s/\Q$ENV{COLLECTOR_SECRET}\E/[REDACTED]/g if length $ENV{COLLECTOR_SECRET};
+        s{(://)[^/\s:@]+:[^/\s]*@}{$1\[REDACTED\]@}g;
+        $in_pem = 1 if /-----BEGIN [A-Z ]*PRIVATE KEY-----/;
+        if ($in_pem) {
+            $in_pem = 0 if /-----END [A-Z ]*PRIVATE KEY-----/;
+            s{[A-Za-z0-9+/=]{16,}}{[REDACTED]}g;
+        }
+        s/([\w-]*(?:(?:secret|token|password|passwd|authorization|credential)[\w-]*|key)["\x27]?\s*[:=]\s*)(?:(["\x27])((?:Bearer\s+)?)(?:\\.|(?!\2).)*(?:\2|$)|((?:Bearer\s+)?)[^\s"\x27,&}][^,&}\r\n]*)/defined $2 ? "$1$2$3\[REDACTED\]$2" : "$1$4\[REDACTED\]"/gie;
+    ' "$1"
+}
+
+# copy_logs <source dir> <destination dir>, keeping only the most recent lines of each file
+copy_logs() {
+    local source="$1"
+    local destination="$2"
+    local file
+
+    [ -d "$source" ] || return 0
+    while IFS= read -r file; do
+        mkdir -p "$destination/$(dirname "${file#"$source"/}")"
+        tail -n "$SUPPORT_BUNDLE_LOG_LINES" "$file" > "$destination/${file#"$source"/}"
+    done < <(find "$source" -type f -name '*.log')
+}
+
+support_bundle() {
+    local timestamp
+    local work_dir
+    local bundle_name
+    local bundle_dir
+    local bundle_file
+    local file
+    local var
+
+    timestamp=$(date -u +%Y%m%d-%H%M%S)
+    bundle_name="support-bundle-${HOSTNAME:-$(hostname)}-$timestamp"
+    bundle_file="$MANIFEST_DIR/$bundle_name.tar.gz"
+    work_dir=$(mktemp -d)
+    bundle_dir="$work_dir/$bundle_name"
+
+    echo "Collecting support bundle..."
+    mkdir -p "$bundle_dir/logs" "$bundle_dir/config"
+
+    {
+        echo "Generated at: $(date -u +%Y-%m-%dT%H:%M:%SZ)"
+        echo "Hostname: ${HOSTNAME:-$(hostname)}"
+        echo "Collector version: ${COLLECTOR_VERSION:-unknown}"
+        echo "Installed as: ${INSTALLED_AS:-unknown}"
+        echo "Profile: ${COLLECTOR_PROFILE:-standard}"
+        echo "Vector: $(vector --version 2>&1 | head -n 1)"
+        echo "Kernel: $(uname -a)"
+        [ -f /etc/os-release ] && grep '^PRETTY_NAME=' /etc/os-release
+        [ -f /host/etc/os-release ] && echo "Host $(grep '^PRETTY_NAME=' /host/etc/os-release)"
+    } > "$bundle_dir/versions.txt" 2>&1
+
+    for var in $SUPPORT_BUNDLE_ENV_VARS; do
+        echo "$var=${!var:-}"
+    done > "$bundle_dir/environment.txt"
+    # Phrased without "=" so the redaction below keeps it readable
+    echo "COLLECTOR_SECRET is $([ -n "$COLLECTOR_SECRET" ] && echo "set" || echo "not set")" >> "$bundle_dir/environment.txt"
+    supervisorctl -c "$COLLECTOR_SUPERVISOR_CONF" status > "$bundle_dir/supervisor-status.txt" 2>&1
+    # Strip colors so the output reads well in a text editor
+    (doctor) 2>&1 | sed 's/\x1b\[[0-9;]*m//g' > "$bundle_dir/doctor.txt"
+    df -h "$MANIFEST_DIR" /var/lib/vector > "$bundle_dir/disk-usage.txt" 2>&1
+
+    copy_logs /var/log/supervisor "$bundle_dir/logs/supervisor"
+    copy_logs "$MANIFEST_DIR/logs" "$bundle_dir/logs/better-stack"
+
+    for file in "$MANIFEST_FILE" "$EBPF_PREFLIGHT_FILE" "$COLLECTOR_SUPERVISOR_CONF"; do
+        [ -f "$file" ] && cp "$file" "$bundle_dir/config/"
+    done
+    if [ -d "$VECTOR_CONFIG_DIR" ]; then
+        readlink -f "$VECTOR_CONFIG_DIR" > "$bundle_dir/config/vector-config-target.txt"
+        cp -rL "$VECTOR_CONFIG_DIR" "$bundle_dir/config/vector"
+    fi
+
+    while IFS= read -r file; do
+        redact_file "$file"
+    done < <(find "$bundle_dir" -type f)
+
+    if ! tar -czf "$bundle_file" -C "$work_dir" "$bundle_name"; then
+        rm -rf "$work_dir"
+        echo -e "${RED}Failed to write support bundle to $bundle_file${NC}" >&2
+        return 1
+    fi
+    rm -rf "$work_dir"
+
+    echo -e "${GREEN}Support bundle written to $bundle_file${NC}"
+    echo "The directory is mounted from the host, so the file is also available there at the same path."
+    echo "Secrets are redacted, but please review the contents before sharing."
+}
+
+case "${1:-help}" in
+    doctor)
+        doctor
+        ;;
+    support-bundle)
+        support_bundle
+        ;;
+    test-connection)
+        test_connection
+        ;;
+    send-test)
+        shift
+        send_test "$@"
+        ;;
+    help|-h|--help)
+        usage
+        ;;
+    *)
+        echo "Unknown command: $1" >&2
+        usage >&2
+        exit 1
+        ;;
+esac
@@ -35,6 +35,27 @@ if [ -z "$HOSTNAME" ]; then
   export HOSTNAME
 fi
 
+# Normalize outbound proxy settings so curl, Ruby, certbot, cluster agent and Vector all see the same values
+# curl ignores uppercase HTTP_PROXY (it only reads http_proxy), other variables are accepted in either case
+for proxy_var in HTTP_PROXY HTTPS_PROXY NO_PROXY; do
+  proxy_value="${!proxy_var:-}"
+  lower_var=$(echo "$proxy_var" | tr '[:upper:]' '[:lower:]')
+  proxy_value="${proxy_value:-${!lower_var:-}}"
+  if [ -n "$proxy_value" ]; then
+    export "$proxy_var=$proxy_value" "$lower_var=$proxy_value"
+  else
+    unset "$proxy_var" "$lower_var"
+  fi
+done
+
+# Traffic within the container (cluster agent remote write and health checks against Vector) must never go through the outbound proxy
+if [ -n "${HTTP_PROXY:-}" ] || [ -n "${HTTPS_PROXY:-}" ]; then
+  NO_PROXY="localhost,127.0.0.1,::1${NO_PROXY:+,$NO_PROXY}"
+  export NO_PROXY no_proxy="$NO_PROXY"
+  # Strip user:pass@ so proxy credentials don't end up in container logs
+  echo "Using outbound proxy (HTTP_PROXY=$(echo "${HTTP_PROXY:-}" | sed -E 's#(://).*@#\1#'), HTTPS_PROXY=$(echo "${HTTPS_PROXY:-}" | sed -E 's#(://).*@#\1#'), NO_PROXY=$NO_PROXY)"
+fi
+
 # Ensure logs directories exist in volume at runtime
 mkdir -p /var/lib/better-stack/logs/collector
 mkdir -p /var/lib/better-stack/logs/ebpf
@@ -29,6 +29,10 @@
 # - ENABLE_DOCKERPROBE: Enable Docker container metadata collection (default: true)
 # - COLLECT_OTEL_HTTP_PORT: Port to expose for OTel HTTP ingestion (e.g., 4318)
 # - COLLECT_OTEL_GRPC_PORT: Port to expose for OTel gRPC ingestion (e.g., 4317)
+# - COLLECTOR_PROFILE: standard (default) or minimal (skips eBPF agent, for 512MB-1GB devices)
+# - HTTP_PROXY: Proxy for outbound HTTP traffic from the collector
+# - HTTPS_PROXY: Proxy for outbound HTTPS traffic from the collector
+# - NO_PROXY: Comma-separated hosts that bypass the proxy, include in-cluster scrape targets (localhost is always added)
 #
 # Node filtering:
 # - To deploy only to specific nodes, label them beforehand:
@@ -75,6 +79,13 @@ if [[ "$ACTION" != "install" && "$ACTION" != "uninstall" && "$ACTION" != "force_
     exit 1
 fi
 
+# Validate COLLECTOR_PROFILE parameter
+if [[ -n "${COLLECTOR_PROFILE:-}" && "$COLLECTOR_PROFILE" != "standard" && "$COLLECTOR_PROFILE" != "minimal" ]]; then
+    print_red "Error: Invalid COLLECTOR_PROFILE parameter: $COLLECTOR_PROFILE"
+    echo "Valid profiles: standard, minimal"
+    exit 1
+fi
+
 # Check required environment variables
 if [[ -z "${MANAGER_NODE:-}" ]]; then
     print_red "Error: MANAGER_NODE environment variable is required"
@@ -98,6 +109,11 @@ CLUSTER_COLLECTOR="${CLUSTER_COLLECTOR:-false}"
 ENABLE_DOCKERPROBE="${ENABLE_DOCKERPROBE:-true}"
 COLLECT_OTEL_HTTP_PORT="${COLLECT_OTEL_HTTP_PORT:-}"
 COLLECT_OTEL_GRPC_PORT="${COLLECT_OTEL_GRPC_PORT:-}"
+COLLECTOR_PROFILE="${COLLECTOR_PROFILE:-}"
+# Outbound proxy settings, lowercase variants are accepted as well
+HTTP_PROXY="${HTTP_PROXY:-${http_proxy:-}}"
+HTTPS_PROXY="${HTTPS_PROXY:-${https_proxy:-}}"
+NO_PROXY="${NO_PROXY:-${no_proxy:-}}"
 
 # GitHub raw URL base for downloading compose files
 GITHUB_RAW_BASE="https://raw.githubusercontent.com/BetterStackHQ/collector/main"
@@ -178,6 +194,10 @@ deploy_collector_stack() {
     local cluster_collector="$CLUSTER_COLLECTOR"
     local otel_http_port="$COLLECT_OTEL_HTTP_PORT"
     local otel_grpc_port="$COLLECT_OTEL_GRPC_PORT"
+    local profile="$COLLECTOR_PROFILE"
+    local proxy_http="$HTTP_PROXY"
+    local proxy_https="$HTTPS_PROXY"
+    local proxy_no="$NO_PROXY"
     local use_labeled_nodes="$USE_LABELED_NODES"
 
     $SSH_CMD "$MANAGER_NODE" /bin/bash <<EOF
@@ -335,6 +355,10 @@ MOUNT_ENTRY
         CLUSTER_COLLECTOR="$cluster_collector" \\
         COLLECT_OTEL_HTTP_PORT="$otel_http_port" \\
         COLLECT_OTEL_GRPC_PORT="$otel_grpc_port" \\
+        COLLECTOR_PROFILE="$profile" \\
+        HTTP_PROXY="$proxy_http" \\
+        HTTPS_PROXY="$proxy_https" \\
+        NO_PROXY="$proxy_no" \\
             docker stack deploy -c docker-compose.yml better-stack
 
         # Trigger service reconciliation to schedule tasks on newly labeled nodes
@@ -482,6 +506,35 @@ EOF
     fi
 }
 
+# Function to prepare a node for the minimal profile, which runs the collector without the eBPF agent
+prepare_minimal_node() {
+    local node="$1"
+    local node_target
+    node_target=$(get_node_target "$node")
+
+    # Remove eBPF agent left over from a previous standard deployment
+    uninstall_ebpf_from_node "$node" || return 1
+
+    # Create shared directory (must exist before swarm service starts)
+    if $SSH_CMD "$node_target" "mkdir -p /var/lib/better-stack && chmod 755 /var/lib/better-stack" </dev/null; then
+        print_green "✓ Node $node prepared for minimal profile"
+    else
+        print_red "✗ Failed to prepare $node for minimal profile"
+        return 1
+    fi
+}
+
+# Function to set up a node before collector stack deployment, depending on COLLECTOR_PROFILE
+prepare_node() {
+    local node="$1"
+
+    if [[ "$COLLECTOR_PROFILE" == "minimal" ]]; then
+        prepare_minimal_node "$node"
+    else
+        deploy_ebpf_to_node "$node"
+    fi
+}
+
 # Function to uninstall collector stack
 uninstall_collector_stack() {
     print_blue "Removing collector stack from swarm..."
@@ -520,13 +573,13 @@ fi
 # Main execution
 case "$ACTION" in
     "install")
-        # Deploy eBPF agent to each node first (this also creates /var/lib/better-stack directory)
+        # Deploy eBPF agent (or just the shared directory for minimal profile) to each node first
         CURRENT=0
         for NODE in $NODES; do
             ((CURRENT++))
-            print_blue "Installing eBPF agent on node: $NODE ($CURRENT/$NODE_COUNT)"
-            if ! deploy_ebpf_to_node "$NODE"; then
-                print_red "Aborting deployment due to eBPF agent installation failure on $NODE"
+            print_blue "Preparing node: $NODE ($CURRENT/$NODE_COUNT)"
+            if ! prepare_node "$NODE"; then
+                print_red "Aborting deployment due to node preparation failure on $NODE"
                 exit 1
             fi
             echo
@@ -538,7 +591,11 @@ case "$ACTION" in
         print_green "✓ Better Stack collector successfully installed on all swarm nodes!"
         echo
         print_blue "Collector is running as a Docker Swarm global service."
-        print_blue "eBPF agent is running as docker-compose on each node."
+        if [[ "$COLLECTOR_PROFILE" == "minimal" ]]; then
+            print_blue "Minimal profile: eBPF agent is not installed."
+        else
+            print_blue "eBPF agent is running as docker-compose on each node."
+        fi
         ;;
 
     "uninstall")
@@ -576,13 +633,13 @@ case "$ACTION" in
         print_blue "Waiting for cleanup..."
         sleep 5
 
-        # Deploy eBPF agent to each node first (this also creates /var/lib/better-stack directory)
+        # Deploy eBPF agent (or just the shared directory for minimal profile) to each node first
         CURRENT=0
         for NODE in $NODES; do
             ((CURRENT++))
-            print_blue "Installing eBPF agent on node: $NODE ($CURRENT/$NODE_COUNT)"
-            if ! deploy_ebpf_to_node "$NODE"; then
-                print_red "Aborting force upgrade due to eBPF agent installation failure on $NODE"
+            print_blue "Preparing node: $NODE ($CURRENT/$NODE_COUNT)"
+            if ! prepare_node "$NODE"; then
+                print_red "Aborting force upgrade due to node preparation failure on $NODE"
                 exit 1
             fi
             echo
@@ -0,0 +1,133 @@
+#!/bin/bash
+
+# eBPF pre-flight capability check
+#
+# Probes the host for what OBI and node agent need (kernel version, BTF, memlock, capabilities,
+# tracefs, cgroup version) before supervisord starts them. Results are logged and written as JSON
+# to the shared volume, so runtime components can report them and skip programs that cannot work
+# instead of letting them crash-loop.
+#
+# Always exits 0, a failed check must never prevent the container from starting.
+
+set -uo pipefail
+
+PREFLIGHT_FILE="${PREFLIGHT_FILE:-/var/lib/better-stack/ebpf-preflight.json}"
+
+# Minimum kernel for BTF/CO-RE based eBPF programs
+MIN_KERNEL_MAJOR=5
+MIN_KERNEL_MINOR=8
+
+# Kernels from 5.11 account eBPF memory via memcg instead of RLIMIT_MEMLOCK
+MEMCG_KERNEL_MAJOR=5
+MEMCG_KERNEL_MINOR=11
+
+CHECKS_JSON="{}"
+ALL_OK=true
+
+log() {
+  echo "[preflight] $1"
+}
+
+# Record a check result: record_check <name> <ok:true|false> <value> <hint>
+record_check() {
+  local name="$1"
+  local ok="$2"
+  local value="$3"
+  local hint="$4"
+
+  if [ "$ok" = true ]; then
+    log "$name: ok ($value)"
+  else
+    ALL_OK=false
+    log "$name: FAILED ($value) - $hint"
+  fi
+
+  CHECKS_JSON=$(jq -c --arg name "$name" --argjson ok "$ok" --arg value "$value" --arg hint "$hint" \
+    '.[$name] = {ok: $ok, value: $value} + (if $ok then {} else {hint: $hint} end)' <<< "$CHECKS_JSON")
+}
+
+# Returns 0 if running kernel is at least $1.$2
+kernel_at_least() {
+  [ "$KERNEL_MAJOR" -gt "$1" ] || { [ "$KERNEL_MAJOR" -eq "$1" ] && [ "$KERNEL_MINOR" -ge "$2" ]; }
+}
+
+KERNEL_RELEASE=$(uname -r)
+KERNEL_MAJOR=$(echo "$KERNEL_RELEASE" | awk -F. '{print $1 + 0}')
+KERNEL_MINOR=$(echo "$KERNEL_RELEASE" | awk -F. '{print $2 + 0}')
+
+# BTF type information
+if [ -r /sys/kernel/btf/vmlinux ]; then
+  HAS_BTF=true
+  record_check btf true "/sys/kernel/btf/vmlinux" ""
+else
+  HAS_BTF=false
+  record_check btf false "missing" "kernel must be built with CONFIG_DEBUG_INFO_BTF=y"
+fi
+
+# Kernel version, BTF is authoritative: older vendor kernels (e.g. RHEL 8 on 4.18) backport it
+if kernel_at_least "$MIN_KERNEL_MAJOR" "$MIN_KERNEL_MINOR"; then
+  record_check kernel_version true "$KERNEL_RELEASE" ""
+elif [ "$HAS_BTF" = true ]; then
+  record_check kernel_version true "$KERNEL_RELEASE, backported BTF" ""
+else
+  record_check kernel_version false "$KERNEL_RELEASE" "kernel ${MIN_KERNEL_MAJOR}.${MIN_KERNEL_MINOR}+ (or a vendor kernel with backported BTF) is required for eBPF instrumentation"
+fi
+
+# Locked memory limit (only relevant before memcg-based accounting)
+MEMLOCK=$(ulimit -l)
+if [ "$MEMLOCK" = "unlimited" ] || kernel_at_least "$MEMCG_KERNEL_MAJOR" "$MEMCG_KERNEL_MINOR"; then
+  record_check memlock true "$MEMLOCK" ""
+else
+  record_check memlock false "$MEMLOCK" "raise the memlock ulimit for the container (e.g. ulimits: memlock: -1)"
+fi
+
+# Effective capabilities (bit numbers from linux/capability.h)
+CAP_EFF=$(awk '/^CapEff:/ {print $2}' /proc/self/status)
+MISSING_CAPS=""
+for cap in SYS_ADMIN:21 SYS_PTRACE:19 NET_ADMIN:12 SYS_RESOURCE:24; do
+  if [ $(( (0x$CAP_EFF >> ${cap##*:}) & 1 )) -ne 1 ]; then
+    MISSING_CAPS="${MISSING_CAPS:+$MISSING_CAPS,}CAP_${cap%%:*}"
+  fi
+done
+if [ -z "$MISSING_CAPS" ]; then
+  record_check capabilities true "0x$CAP_EFF" ""
+else
+  record_check capabilities false "missing $MISSING_CAPS" "run the eBPF container with privileged: true"
+fi
+
+# tracefs for kprobes/tracepoints
+if [ -d /sys/kernel/tracing/events ] || [ -d /sys/kernel/debug/tracing/events ]; then
+  record_check tracefs true "mounted" ""
+else
+  record_check tracefs false "missing" "mount /sys/kernel/tracing (and /sys/kernel/debug) into the container"
+fi
+
+# cgroup version
+CGROUP_FS=$(stat -fc %T /sys/fs/cgroup 2>/dev/null || echo "unknown")
+case "$CGROUP_FS" in
+  cgroup2fs)
+    record_check cgroup true "v2" ""
+    ;;
+  tmpfs)
+    record_check cgroup true "v1" ""
+    ;;
+  *)
+    record_check cgroup false "$CGROUP_FS" "mount /sys/fs/cgroup into the container"
+    ;;
+esac
+
+mkdir -p "$(dirname "$PREFLIGHT_FILE")"
+TEMP_FILE=$(mktemp)
+jq -n --arg checked_at "$(date -u +%Y-%m-%dT%H:%M:%SZ)" --arg kernel "$KERNEL_RELEASE" \
+  --argjson ok "$ALL_OK" --argjson checks "$CHECKS_JSON" \
+  '{checked_at: $checked_at, kernel_release: $kernel, ok: $ok, checks: $checks}' > "$TEMP_FILE" \
+  && mv "$TEMP_FILE" "$PREFLIGHT_FILE"
+rm -f "$TEMP_FILE"
+
+if [ "$ALL_OK" = true ]; then
+  log "All checks passed, results written to $PREFLIGHT_FILE"
+else
+  log "Some checks failed, eBPF components may not work, results written to $PREFLIGHT_FILE"
+fi
+
+exit 0
@@ -19,5 +19,8 @@ if [ -z "$HOSTNAME" ]; then
   export HOSTNAME
 fi
 
+# Probe eBPF capabilities before any agent starts, results go to /var/lib/better-stack/ebpf-preflight.json
+/preflight.sh || echo "eBPF pre-flight check failed to run"
+
 # Start supervisord
 exec /usr/bin/supervisord -c "$SUPERVISORD_CONF"
@@ -68,6 +68,24 @@ fi
 MOUNT_HOST_PATHS="${MOUNT_HOST_PATHS:-}"
 COLLECT_OTEL_HTTP_PORT="${COLLECT_OTEL_HTTP_PORT:-}"
 COLLECT_OTEL_GRPC_PORT="${COLLECT_OTEL_GRPC_PORT:-}"
+COLLECTOR_PROFILE="${COLLECTOR_PROFILE:-}"
+# Outbound proxy settings, lowercase variants are accepted as well
+HTTP_PROXY="${HTTP_PROXY:-${http_proxy:-}}"
+HTTPS_PROXY="${HTTPS_PROXY:-${https_proxy:-}}"
+NO_PROXY="${NO_PROXY:-${no_proxy:-}}"
+
+# Validate resource profile, minimal skips the eBPF agent for low-memory devices (512MB-1GB RAM)
+if [ -n "$COLLECTOR_PROFILE" ] && [ "$COLLECTOR_PROFILE" != "standard" ] && [ "$COLLECTOR_PROFILE" != "minimal" ]; then
+    echo "Invalid COLLECTOR_PROFILE: $COLLECTOR_PROFILE (valid profiles: standard, minimal)"
+    exit 1
+fi
+
+# Services to run, empty means all services in the compose file
+COMPOSE_SERVICES=""
+if [ "$COLLECTOR_PROFILE" = "minimal" ]; then
+    echo "Using minimal profile, eBPF agent will not be installed"
+    COMPOSE_SERVICES="collector"
+fi
 
 # Set hostname if not provided (use empty string HOSTNAME="" to trigger runtime detection via uts:host)
 if [ -z "${HOSTNAME+x}" ]; then
@@ -282,7 +300,11 @@ ENABLE_DOCKERPROBE="$ENABLE_DOCKERPROBE" \
 HOSTNAME="$HOSTNAME" \
 COLLECT_OTEL_HTTP_PORT="$COLLECT_OTEL_HTTP_PORT" \
 COLLECT_OTEL_GRPC_PORT="$COLLECT_OTEL_GRPC_PORT" \
-    $COMPOSE_CMD -p better-stack-collector pull
+COLLECTOR_PROFILE="$COLLECTOR_PROFILE" \
+HTTP_PROXY="$HTTP_PROXY" \
+HTTPS_PROXY="$HTTPS_PROXY" \
+NO_PROXY="$NO_PROXY" \
+    $COMPOSE_CMD -p better-stack-collector pull $COMPOSE_SERVICES
 
 if [ "$COMPOSE_CMD" = "docker-compose" ]; then
     # On docker-compose v1, try to stop and remove the container first with a 90s grace period
@@ -294,6 +316,12 @@ fi
 docker stop better-stack-beyla 2>/dev/null || true
 docker rm better-stack-beyla 2>/dev/null || true
 
+# Remove eBPF agent left over from a previous standard install when switching to the minimal profile
+if [ "$COLLECTOR_PROFILE" = "minimal" ]; then
+    docker stop better-stack-ebpf 2>/dev/null || true
+    docker rm better-stack-ebpf 2>/dev/null || true
+fi
+
 # Run containers
 COLLECTOR_SECRET="$COLLECTOR_SECRET" \
 BASE_URL="$BASE_URL" \
@@ -302,4 +330,8 @@ ENABLE_DOCKERPROBE="$ENABLE_DOCKERPROBE" \
 HOSTNAME="$HOSTNAME" \
 COLLECT_OTEL_HTTP_PORT="$COLLECT_OTEL_HTTP_PORT" \
 COLLECT_OTEL_GRPC_PORT="$COLLECT_OTEL_GRPC_PORT" \
-    $COMPOSE_CMD -p better-stack-collector up -d --no-build
+COLLECTOR_PROFILE="$COLLECTOR_PROFILE" \
+HTTP_PROXY="$HTTP_PROXY" \
+HTTPS_PROXY="$HTTPS_PROXY" \
+NO_PROXY="$NO_PROXY" \
+    $COMPOSE_CMD -p better-stack-collector up -d --no-build $COMPOSE_SERVICES